@@ -0,0 +1,320 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Header names GitLab sets on webhook deliveries.
+//
+// GitLab API docs: https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html
+const (
+	eventTypeHeader  = "X-Gitlab-Event"
+	eventTokenHeader = "X-Gitlab-Token"
+)
+
+// Event type values as sent in the X-Gitlab-Event header, one per struct
+// ParseWebhook knows how to decode.
+const (
+	EventTypePush         = "Push Hook"
+	EventTypeTagPush      = "Tag Push Hook"
+	EventTypeMergeRequest = "Merge Request Hook"
+	EventTypeIssue        = "Issue Hook"
+	EventTypePipeline     = "Pipeline Hook"
+	EventTypeNote         = "Note Hook"
+	EventTypeWikiPage     = "Wiki Page Hook"
+)
+
+// ErrInvalidWebhookToken is returned by WebhookEventType when the caller
+// supplies a non-empty secret token and the X-Gitlab-Token header does not
+// match it.
+var ErrInvalidWebhookToken = errors.New("gitlab: invalid webhook secret token")
+
+// WebhookEventType returns the event type of a webhook request, as reported
+// in the X-Gitlab-Event header, for use with ParseWebhook. If secretToken is
+// non-empty, it is compared against the X-Gitlab-Token header and
+// ErrInvalidWebhookToken is returned on mismatch, letting callers reject
+// forged deliveries before they look at the event type at all.
+//
+// GitLab API docs:
+// https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html#validate-payloads-by-using-a-secret-token
+func WebhookEventType(r *http.Request, secretToken string) (string, error) {
+	if secretToken != "" && r.Header.Get(eventTokenHeader) != secretToken {
+		return "", ErrInvalidWebhookToken
+	}
+	return r.Header.Get(eventTypeHeader), nil
+}
+
+// ParseWebhook parses a GitLab webhook payload into the concrete event
+// struct matching eventType (as returned by WebhookEventType), so callers
+// don't have to know the event shape up front.
+func ParseWebhook(eventType string, payload []byte) (interface{}, error) {
+	var event interface{}
+
+	switch eventType {
+	case EventTypePush:
+		event = &PushEvent{}
+	case EventTypeTagPush:
+		event = &TagPushEvent{}
+	case EventTypeMergeRequest:
+		event = &MergeEvent{}
+	case EventTypeIssue:
+		event = &IssueEvent{}
+	case EventTypePipeline:
+		event = &PipelineEvent{}
+	case EventTypeNote:
+		event = &NoteEvent{}
+	case EventTypeWikiPage:
+		event = &WikiPageEvent{}
+	default:
+		return nil, fmt.Errorf("unexpected event type: %s", eventType)
+	}
+
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// HookProject is the "project" object embedded in most webhook payloads.
+type HookProject struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	WebURL            string `json:"web_url"`
+	AvatarURL         string `json:"avatar_url"`
+	GitSSHURL         string `json:"git_ssh_url"`
+	GitHTTPURL        string `json:"git_http_url"`
+	Namespace         string `json:"namespace"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	DefaultBranch     string `json:"default_branch"`
+	VisibilityLevel   int    `json:"visibility_level"`
+}
+
+// HookUser is the minimal "user" object embedded in most webhook payloads.
+type HookUser struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// PushEventCommitAuthor is the "author" object of a single commit inside a
+// PushEvent or TagPushEvent.
+type PushEventCommitAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// PushEventCommit is a single commit as reported by a push or tag push
+// webhook payload. GitLab does not include blob sizes here, only the paths
+// touched, so CheckPushEvent cannot evaluate MaxFileSizeMB from this alone.
+type PushEventCommit struct {
+	ID        string                `json:"id"`
+	Message   string                `json:"message"`
+	Title     string                `json:"title"`
+	Timestamp *time.Time            `json:"timestamp"`
+	URL       string                `json:"url"`
+	Author    PushEventCommitAuthor `json:"author"`
+	Added     []string              `json:"added"`
+	Modified  []string              `json:"modified"`
+	Removed   []string              `json:"removed"`
+}
+
+// PushEvent represents a push webhook event.
+//
+// GitLab API docs:
+// https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html#push-events
+type PushEvent struct {
+	ObjectKind        string             `json:"object_kind"`
+	EventName         string             `json:"event_name"`
+	Before            string             `json:"before"`
+	After             string             `json:"after"`
+	Ref               string             `json:"ref"`
+	CheckoutSHA       string             `json:"checkout_sha"`
+	UserID            int                `json:"user_id"`
+	UserName          string             `json:"user_name"`
+	UserUsername      string             `json:"user_username"`
+	UserEmail         string             `json:"user_email"`
+	UserAvatar        string             `json:"user_avatar"`
+	ProjectID         int                `json:"project_id"`
+	Project           HookProject        `json:"project"`
+	Commits           []*PushEventCommit `json:"commits"`
+	TotalCommitsCount int                `json:"total_commits_count"`
+}
+
+// TagPushEvent represents a tag push webhook event. Its shape mirrors
+// PushEvent, without the UserUsername and UserEmail fields.
+//
+// GitLab API docs:
+// https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html#tag-events
+type TagPushEvent struct {
+	ObjectKind        string             `json:"object_kind"`
+	EventName         string             `json:"event_name"`
+	Before            string             `json:"before"`
+	After             string             `json:"after"`
+	Ref               string             `json:"ref"`
+	CheckoutSHA       string             `json:"checkout_sha"`
+	UserID            int                `json:"user_id"`
+	UserName          string             `json:"user_name"`
+	UserAvatar        string             `json:"user_avatar"`
+	ProjectID         int                `json:"project_id"`
+	Project           HookProject        `json:"project"`
+	Commits           []*PushEventCommit `json:"commits"`
+	TotalCommitsCount int                `json:"total_commits_count"`
+}
+
+// MergeEvent represents a merge request webhook event.
+//
+// GitLab API docs:
+// https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html#merge-request-events
+type MergeEvent struct {
+	ObjectKind       string      `json:"object_kind"`
+	User             HookUser    `json:"user"`
+	Project          HookProject `json:"project"`
+	ObjectAttributes struct {
+		ID           int    `json:"id"`
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		State        string `json:"state"`
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+		URL          string `json:"url"`
+		Action       string `json:"action"`
+	} `json:"object_attributes"`
+	Labels []struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	} `json:"labels"`
+}
+
+// IssueEvent represents an issue webhook event.
+//
+// GitLab API docs:
+// https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html#issue-events
+type IssueEvent struct {
+	ObjectKind       string      `json:"object_kind"`
+	User             HookUser    `json:"user"`
+	Project          HookProject `json:"project"`
+	ObjectAttributes struct {
+		ID          int    `json:"id"`
+		IID         int    `json:"iid"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		State       string `json:"state"`
+		URL         string `json:"url"`
+		Action      string `json:"action"`
+	} `json:"object_attributes"`
+	Assignees []HookUser `json:"assignees"`
+	Labels    []struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	} `json:"labels"`
+}
+
+// PipelineEvent represents a pipeline webhook event.
+//
+// GitLab API docs:
+// https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html#pipeline-events
+type PipelineEvent struct {
+	ObjectKind       string      `json:"object_kind"`
+	User             HookUser    `json:"user"`
+	Project          HookProject `json:"project"`
+	ObjectAttributes struct {
+		ID       int      `json:"id"`
+		Ref      string   `json:"ref"`
+		Tag      bool     `json:"tag"`
+		SHA      string   `json:"sha"`
+		Status   string   `json:"status"`
+		Stages   []string `json:"stages"`
+		Duration int      `json:"duration"`
+	} `json:"object_attributes"`
+	Commit *PushEventCommit `json:"commit"`
+	Builds []struct {
+		ID     int    `json:"id"`
+		Stage  string `json:"stage"`
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	} `json:"builds"`
+}
+
+// NoteEvent represents a comment webhook event. A commit the comment is
+// attached to is decoded into Commit; a merge request, issue, or snippet is
+// left as raw JSON since its shape depends on ObjectAttributes.NoteableType.
+//
+// GitLab API docs:
+// https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html#comment-events
+type NoteEvent struct {
+	ObjectKind       string      `json:"object_kind"`
+	User             HookUser    `json:"user"`
+	ProjectID        int         `json:"project_id"`
+	Project          HookProject `json:"project"`
+	ObjectAttributes struct {
+		ID           int    `json:"id"`
+		Note         string `json:"note"`
+		NoteableType string `json:"noteable_type"`
+		URL          string `json:"url"`
+	} `json:"object_attributes"`
+	Commit       *PushEventCommit `json:"commit,omitempty"`
+	MergeRequest json.RawMessage  `json:"merge_request,omitempty"`
+	Issue        json.RawMessage  `json:"issue,omitempty"`
+	Snippet      json.RawMessage  `json:"snippet,omitempty"`
+}
+
+// WikiPageEvent represents a wiki page webhook event.
+//
+// GitLab API docs:
+// https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html#wiki-page-events
+type WikiPageEvent struct {
+	ObjectKind string      `json:"object_kind"`
+	User       HookUser    `json:"user"`
+	Project    HookProject `json:"project"`
+	Wiki       struct {
+		WebURL     string `json:"web_url"`
+		GitSSHURL  string `json:"git_ssh_url"`
+		GitHTTPURL string `json:"git_http_url"`
+	} `json:"wiki"`
+	ObjectAttributes struct {
+		Title   string `json:"title"`
+		Content string `json:"content"`
+		Format  string `json:"format"`
+		Message string `json:"message"`
+		Slug    string `json:"slug"`
+		URL     string `json:"url"`
+		Action  string `json:"action"`
+	} `json:"object_attributes"`
+}
+
+// CheckPushEvent evaluates the push rules against every commit in a push
+// webhook event, reusing Validate's regex checks. GitLab's push payload
+// does not report blob sizes, so MaxFileSizeMB can never trigger here; use
+// Validate directly against commits fetched from the API if that check
+// matters for your use case.
+func (p *PushRules) CheckPushEvent(ev *PushEvent) []PushRuleViolation {
+	var violations []PushRuleViolation
+
+	for _, c := range ev.Commits {
+		commit := &Commit{
+			Message:        c.Message,
+			CommitterEmail: c.Author.Email,
+		}
+
+		files := make([]CommitFile, 0, len(c.Added)+len(c.Modified))
+		for _, path := range c.Added {
+			files = append(files, CommitFile{Path: path})
+		}
+		for _, path := range c.Modified {
+			files = append(files, CommitFile{Path: path})
+		}
+
+		violations = append(violations, p.Validate(commit, files)...)
+	}
+
+	return violations
+}
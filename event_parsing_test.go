@@ -0,0 +1,165 @@
+package gitlab
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookEventType(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	req.Header.Set(eventTypeHeader, EventTypePush)
+	req.Header.Set(eventTokenHeader, "s3cr3t")
+
+	eventType, err := WebhookEventType(req, "s3cr3t")
+	if err != nil {
+		t.Fatalf("WebhookEventType returned error: %v", err)
+	}
+	if eventType != EventTypePush {
+		t.Errorf("eventType = %q, want %q", eventType, EventTypePush)
+	}
+}
+
+func TestWebhookEventTypeTokenMismatch(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	req.Header.Set(eventTypeHeader, EventTypePush)
+	req.Header.Set(eventTokenHeader, "wrong")
+
+	if _, err := WebhookEventType(req, "s3cr3t"); err != ErrInvalidWebhookToken {
+		t.Errorf("err = %v, want %v", err, ErrInvalidWebhookToken)
+	}
+}
+
+func TestParseWebhookPushEvent(t *testing.T) {
+	payload := []byte(`{
+		"object_kind": "push",
+		"ref": "refs/heads/main",
+		"user_name": "Jane Doe",
+		"project_id": 1,
+		"project": {"id": 1, "name": "example"},
+		"total_commits_count": 1,
+		"commits": [{
+			"id": "abc123",
+			"message": "fix: handle nil pointer",
+			"author": {"name": "Jane Doe", "email": "jane@example.com"},
+			"added": ["new.go"],
+			"modified": ["existing.go"]
+		}]
+	}`)
+
+	event, err := ParseWebhook(EventTypePush, payload)
+	if err != nil {
+		t.Fatalf("ParseWebhook returned error: %v", err)
+	}
+
+	pushEvent, ok := event.(*PushEvent)
+	if !ok {
+		t.Fatalf("event type = %T, want *PushEvent", event)
+	}
+	if pushEvent.Ref != "refs/heads/main" {
+		t.Errorf("Ref = %q, want %q", pushEvent.Ref, "refs/heads/main")
+	}
+	if len(pushEvent.Commits) != 1 || pushEvent.Commits[0].Author.Email != "jane@example.com" {
+		t.Fatalf("unexpected commits: %+v", pushEvent.Commits)
+	}
+}
+
+func TestParseWebhookTagPushEvent(t *testing.T) {
+	payload := []byte(`{
+		"object_kind": "tag_push",
+		"ref": "refs/tags/v1.0.0",
+		"project_id": 1
+	}`)
+
+	event, err := ParseWebhook(EventTypeTagPush, payload)
+	if err != nil {
+		t.Fatalf("ParseWebhook returned error: %v", err)
+	}
+
+	tagEvent, ok := event.(*TagPushEvent)
+	if !ok {
+		t.Fatalf("event type = %T, want *TagPushEvent", event)
+	}
+	if tagEvent.Ref != "refs/tags/v1.0.0" {
+		t.Errorf("Ref = %q, want %q", tagEvent.Ref, "refs/tags/v1.0.0")
+	}
+}
+
+func TestParseWebhookMergeEvent(t *testing.T) {
+	payload := []byte(`{
+		"object_kind": "merge_request",
+		"object_attributes": {
+			"id": 99,
+			"iid": 4,
+			"title": "Add feature",
+			"state": "opened",
+			"source_branch": "feature",
+			"target_branch": "main",
+			"action": "open"
+		}
+	}`)
+
+	event, err := ParseWebhook(EventTypeMergeRequest, payload)
+	if err != nil {
+		t.Fatalf("ParseWebhook returned error: %v", err)
+	}
+
+	mergeEvent, ok := event.(*MergeEvent)
+	if !ok {
+		t.Fatalf("event type = %T, want *MergeEvent", event)
+	}
+	if mergeEvent.ObjectAttributes.Action != "open" {
+		t.Errorf("Action = %q, want %q", mergeEvent.ObjectAttributes.Action, "open")
+	}
+}
+
+func TestParseWebhookPipelineEvent(t *testing.T) {
+	payload := []byte(`{
+		"object_kind": "pipeline",
+		"object_attributes": {
+			"id": 31,
+			"ref": "main",
+			"status": "success",
+			"stages": ["build", "test"]
+		}
+	}`)
+
+	event, err := ParseWebhook(EventTypePipeline, payload)
+	if err != nil {
+		t.Fatalf("ParseWebhook returned error: %v", err)
+	}
+
+	pipelineEvent, ok := event.(*PipelineEvent)
+	if !ok {
+		t.Fatalf("event type = %T, want *PipelineEvent", event)
+	}
+	if pipelineEvent.ObjectAttributes.Status != "success" {
+		t.Errorf("Status = %q, want %q", pipelineEvent.ObjectAttributes.Status, "success")
+	}
+}
+
+func TestParseWebhookUnknownEventType(t *testing.T) {
+	if _, err := ParseWebhook("Bogus Hook", []byte(`{}`)); err == nil {
+		t.Error("expected an error for an unknown event type, got nil")
+	}
+}
+
+func TestPushRulesCheckPushEvent(t *testing.T) {
+	commitMessageRegex := "^fix:"
+	rules := &PushRules{CommitMessageRegex: &commitMessageRegex}
+
+	event := &PushEvent{
+		Commits: []*PushEventCommit{
+			{Message: "fix: handle nil pointer", Author: PushEventCommitAuthor{Email: "jane@example.com"}},
+			{Message: "oops", Author: PushEventCommitAuthor{Email: "jane@example.com"}},
+		},
+	}
+
+	violations := rules.CheckPushEvent(event)
+	if len(violations) != 1 {
+		t.Fatalf("len(violations) = %d, want 1: %+v", len(violations), violations)
+	}
+	if violations[0].Field != "commit_message_regex" {
+		t.Errorf("Field = %q, want %q", violations[0].Field, "commit_message_regex")
+	}
+}
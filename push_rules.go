@@ -2,28 +2,148 @@ package gitlab
 
 import (
 	"fmt"
+	"net/http"
 	"net/url"
+	"regexp"
+	"sync"
 	"time"
 )
 
 type PushRules struct {
-	ID                 int        `json:"id,omitempty"`
-	PID                int        `json:"project_id,omitempty"`
-	CommitMessageRegex *string    `json:"commit_message_regex,omitempty"`
-	BranchNameRegex    *string    `json:"branch_name_regex,omitempty"`
-	DenyDeleteTag      *bool      `json:"deny_delete_tag,omitempty"`
-	CreatedAt          *time.Time `json:"created_at,omitempty"`
-	MemberCheck        *bool      `json:"member_check,omitempty"`
-	PreventSecrets     *bool      `json:"prevent_secrets,omitempty"`
-	AuthorEmailRegex   *string    `json:"author_email_regex,omitempty"`
-	FileNameRegex      *string    `json:"file_name_regex,omitempty"`
-	MaxFileSizeMB      *int       `json:"max_file_size,omitempty"`
-}
-
-func (s PushRules) String() string {
+	ID                    int        `json:"id,omitempty"`
+	PID                   int        `json:"project_id,omitempty"`
+	CommitMessageRegex    *string    `json:"commit_message_regex,omitempty"`
+	BranchNameRegex       *string    `json:"branch_name_regex,omitempty"`
+	DenyDeleteTag         *bool      `json:"deny_delete_tag,omitempty"`
+	CreatedAt             *time.Time `json:"created_at,omitempty"`
+	MemberCheck           *bool      `json:"member_check,omitempty"`
+	PreventSecrets        *bool      `json:"prevent_secrets,omitempty"`
+	AuthorEmailRegex      *string    `json:"author_email_regex,omitempty"`
+	FileNameRegex         *string    `json:"file_name_regex,omitempty"`
+	MaxFileSizeMB         *int       `json:"max_file_size,omitempty"`
+	CommitCommitterCheck  *bool      `json:"commit_committer_check,omitempty"`
+	RejectUnsignedCommits *bool      `json:"reject_unsigned_commits,omitempty"`
+	PreventSecretsRegex   *string    `json:"prevent_secrets_regex,omitempty"`
+
+	// compiled lazily by Validate/CheckPushEvent, guarded by reMu, never
+	// marshaled.
+	reMu             sync.Mutex
+	commitMessageRe  *regexp.Regexp
+	authorEmailRe    *regexp.Regexp
+	fileNameRe       *regexp.Regexp
+	preventSecretsRe *regexp.Regexp
+}
+
+func (s *PushRules) String() string {
 	return Stringify(s)
 }
 
+// PushRuleViolation describes a single client-side PushRules check that
+// failed. Callers building pre-receive tooling or CI gates can use Field to
+// key off which rule tripped and Message as a human-readable explanation.
+type PushRuleViolation struct {
+	Field   string
+	Message string
+}
+
+func (v PushRuleViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.Field, v.Message)
+}
+
+// CommitFile describes a single file touched by a commit, as needed by
+// Validate and CheckPushEvent to evaluate FileNameRegex, PreventSecretsRegex,
+// and MaxFileSizeMB without a round trip to the server.
+type CommitFile struct {
+	Path string
+	Size int64
+}
+
+// Validate evaluates the push rules against a commit and the files it
+// touches, entirely client-side. It compiles each configured regex once,
+// caching it on the receiver under reMu so concurrent callers (e.g. a
+// webhook handler calling CheckPushEvent per request against one shared,
+// fetched-once rule set) don't race, and returns every violation found
+// rather than stopping at the first one so callers can report them all at
+// once.
+func (p *PushRules) Validate(commit *Commit, files []CommitFile) []PushRuleViolation {
+	var violations []PushRuleViolation
+
+	if commit != nil {
+		if p.CommitMessageRegex != nil {
+			re, err := p.compileRegex(*p.CommitMessageRegex, &p.commitMessageRe)
+			if err == nil && !re.MatchString(commit.Message) {
+				violations = append(violations, PushRuleViolation{
+					Field:   "commit_message_regex",
+					Message: fmt.Sprintf("commit message does not match %q", *p.CommitMessageRegex),
+				})
+			}
+		}
+
+		if p.AuthorEmailRegex != nil {
+			re, err := p.compileRegex(*p.AuthorEmailRegex, &p.authorEmailRe)
+			if err == nil && !re.MatchString(commit.CommitterEmail) {
+				violations = append(violations, PushRuleViolation{
+					Field:   "author_email_regex",
+					Message: fmt.Sprintf("committer email %q does not match %q", commit.CommitterEmail, *p.AuthorEmailRegex),
+				})
+			}
+		}
+	}
+
+	for _, f := range files {
+		if p.FileNameRegex != nil {
+			re, err := p.compileRegex(*p.FileNameRegex, &p.fileNameRe)
+			if err == nil && re.MatchString(f.Path) {
+				violations = append(violations, PushRuleViolation{
+					Field:   "file_name_regex",
+					Message: fmt.Sprintf("file %q matches forbidden pattern %q", f.Path, *p.FileNameRegex),
+				})
+			}
+		}
+
+		if p.PreventSecretsRegex != nil {
+			re, err := p.compileRegex(*p.PreventSecretsRegex, &p.preventSecretsRe)
+			if err == nil && re.MatchString(f.Path) {
+				violations = append(violations, PushRuleViolation{
+					Field:   "prevent_secrets_regex",
+					Message: fmt.Sprintf("file %q matches the secrets pattern %q", f.Path, *p.PreventSecretsRegex),
+				})
+			}
+		}
+
+		if p.MaxFileSizeMB != nil && *p.MaxFileSizeMB > 0 {
+			maxBytes := int64(*p.MaxFileSizeMB) * 1024 * 1024
+			if f.Size > maxBytes {
+				violations = append(violations, PushRuleViolation{
+					Field:   "max_file_size",
+					Message: fmt.Sprintf("file %q is %d bytes, exceeding the %dMB limit", f.Path, f.Size, *p.MaxFileSizeMB),
+				})
+			}
+		}
+	}
+
+	return violations
+}
+
+// compileRegex compiles pattern, caching the result in *cache so repeated
+// calls against the same PushRules don't pay the compilation cost again.
+// The cache is guarded by reMu since Validate is meant to be called
+// concurrently against one shared PushRules.
+func (p *PushRules) compileRegex(pattern string, cache **regexp.Regexp) (*regexp.Regexp, error) {
+	p.reMu.Lock()
+	defer p.reMu.Unlock()
+
+	if *cache != nil {
+		return *cache, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	*cache = re
+	return re, nil
+}
+
 // GetPushRules gets a specific project's push rules, identified by project ID or
 // NAMESPACE/PROJECT_NAME, which is owned by the authenticated user.
 //
@@ -51,14 +171,17 @@ func (s *ProjectsService) GetPushRule(pid interface{}, options ...OptionFunc) (*
 }
 
 type AddPushRulesOptions struct {
-	CommitMessageRegex *string    `json:"commit_message_regex,omitempty"`
-	BranchNameRegex    *string    `json:"branch_name_regex,omitempty"`
-	DenyDeleteTag      *bool      `json:"deny_delete_tag,omitempty"`
-	MemberCheck        *bool      `json:"member_check,omitempty"`
-	PreventSecrets     *bool      `json:"prevent_secrets,omitempty"`
-	AuthorEmailRegex   *string    `json:"author_email_regex,omitempty"`
-	FileNameRegex      *string    `json:"file_name_regex,omitempty"`
-	MaxFileSizeMB      *int       `json:"max_file_size,omitempty"`
+	CommitMessageRegex    *string `json:"commit_message_regex,omitempty"`
+	BranchNameRegex       *string `json:"branch_name_regex,omitempty"`
+	DenyDeleteTag         *bool   `json:"deny_delete_tag,omitempty"`
+	MemberCheck           *bool   `json:"member_check,omitempty"`
+	PreventSecrets        *bool   `json:"prevent_secrets,omitempty"`
+	AuthorEmailRegex      *string `json:"author_email_regex,omitempty"`
+	FileNameRegex         *string `json:"file_name_regex,omitempty"`
+	MaxFileSizeMB         *int    `json:"max_file_size,omitempty"`
+	CommitCommitterCheck  *bool   `json:"commit_committer_check,omitempty"`
+	RejectUnsignedCommits *bool   `json:"reject_unsigned_commits,omitempty"`
+	PreventSecretsRegex   *string `json:"prevent_secrets_regex,omitempty"`
 }
 
 // AddPushRule adds a push rule to a specified project.
@@ -128,3 +251,202 @@ func (s *ProjectsService) DeletePushRule(pid interface{}, options ...OptionFunc)
 
 	return s.client.Do(req, nil)
 }
+
+// GetGroupPushRule gets the push rules of a specified group, identified by
+// group ID or NAMESPACE/GROUP_NAME, which is owned by the authenticated user.
+//
+// GitLab API docs:
+// https://docs.gitlab.com/ee/api/groups.html#get-group-push-rules
+func (s *GroupsService) GetGroupPushRule(gid interface{}, options ...OptionFunc) (*PushRules, *Response, error) {
+	group, err := parseID(gid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := fmt.Sprintf("groups/%s/push_rule", url.QueryEscape(group))
+
+	req, err := s.client.NewRequest("GET", u, nil, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := new(PushRules)
+	resp, err := s.client.Do(req, p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+// AddGroupPushRule adds a push rule to a specified group.
+//
+// GitLab API docs:
+// https://docs.gitlab.com/ee/api/groups.html#add-group-push-rule
+func (s *GroupsService) AddGroupPushRule(gid interface{}, opt *AddPushRulesOptions, options ...OptionFunc) (*PushRules, *Response, error) {
+	group, err := parseID(gid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := fmt.Sprintf("groups/%s/push_rule", url.QueryEscape(group))
+
+	req, err := s.client.NewRequest("POST", u, opt, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := new(PushRules)
+	resp, err := s.client.Do(req, p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+// EditGroupPushRule edits a push rule for a specified group.
+//
+// GitLab API docs:
+// https://docs.gitlab.com/ee/api/groups.html#edit-group-push-rule
+func (s *GroupsService) EditGroupPushRule(gid interface{}, opt *EditPushRulesOptions, options ...OptionFunc) (*PushRules, *Response, error) {
+	group, err := parseID(gid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := fmt.Sprintf("groups/%s/push_rule", url.QueryEscape(group))
+
+	req, err := s.client.NewRequest("PUT", u, opt, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := new(PushRules)
+	resp, err := s.client.Do(req, p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+// DeleteGroupPushRule removes a push rule from a group. This is an idempotent
+// method and can be called multiple times. Either the push rule is available
+// or not.
+//
+// GitLab API docs:
+// https://docs.gitlab.com/ee/api/groups.html#delete-group-push-rule
+func (s *GroupsService) DeleteGroupPushRule(gid interface{}, options ...OptionFunc) (*Response, error) {
+	group, err := parseID(gid)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("groups/%s/push_rule", url.QueryEscape(group))
+
+	req, err := s.client.NewRequest("DELETE", u, nil, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+// ApplyPushRule reconciles a project's push rule with the desired spec in a
+// single call: it GETs the current rule, POSTs one if none exists yet, PUTs
+// one if the existing rule differs from opt, and otherwise returns the
+// existing rule unchanged. This spares callers building declarative,
+// Terraform-style tooling from handling the GET's 404 themselves and
+// choosing between Add/Edit.
+//
+// If GitLab returned an ETag for the existing rule, it's sent back as
+// If-Match on the PUT so two reconcilers racing to converge the same rule
+// don't silently clobber each other; the PUT fails instead of applying a
+// stale write.
+func (s *ProjectsService) ApplyPushRule(pid interface{}, opt *AddPushRulesOptions, options ...OptionFunc) (*PushRules, *Response, error) {
+	existing, resp, err := s.GetPushRule(pid, options...)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return s.AddPushRule(pid, opt, options...)
+		}
+		return nil, resp, err
+	}
+
+	if pushRuleMatches(existing, opt) {
+		return existing, resp, nil
+	}
+
+	editOpt := EditPushRulesOptions(*opt)
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		options = append(options, ifMatch(etag))
+	}
+
+	return s.EditPushRule(pid, &editOpt, options...)
+}
+
+// ApplyGroupPushRule is the group-level counterpart of ApplyPushRule. See
+// its documentation for the reconciliation and If-Match semantics.
+func (s *GroupsService) ApplyGroupPushRule(gid interface{}, opt *AddPushRulesOptions, options ...OptionFunc) (*PushRules, *Response, error) {
+	existing, resp, err := s.GetGroupPushRule(gid, options...)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return s.AddGroupPushRule(gid, opt, options...)
+		}
+		return nil, resp, err
+	}
+
+	if pushRuleMatches(existing, opt) {
+		return existing, resp, nil
+	}
+
+	editOpt := EditPushRulesOptions(*opt)
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		options = append(options, ifMatch(etag))
+	}
+
+	return s.EditGroupPushRule(gid, &editOpt, options...)
+}
+
+// ifMatch returns an OptionFunc that sets the If-Match header, used by
+// ApplyPushRule/ApplyGroupPushRule to make their PUT conditional on the
+// ETag observed during the preceding GET.
+func ifMatch(etag string) OptionFunc {
+	return func(req *http.Request, _ *url.Values) error {
+		req.Header.Set("If-Match", etag)
+		return nil
+	}
+}
+
+// pushRuleMatches reports whether an existing push rule already matches the
+// desired options, field by field, so ApplyPushRule can skip a no-op write.
+func pushRuleMatches(existing *PushRules, opt *AddPushRulesOptions) bool {
+	return stringPtrEqual(existing.CommitMessageRegex, opt.CommitMessageRegex) &&
+		stringPtrEqual(existing.BranchNameRegex, opt.BranchNameRegex) &&
+		boolPtrEqual(existing.DenyDeleteTag, opt.DenyDeleteTag) &&
+		boolPtrEqual(existing.MemberCheck, opt.MemberCheck) &&
+		boolPtrEqual(existing.PreventSecrets, opt.PreventSecrets) &&
+		stringPtrEqual(existing.AuthorEmailRegex, opt.AuthorEmailRegex) &&
+		stringPtrEqual(existing.FileNameRegex, opt.FileNameRegex) &&
+		intPtrEqual(existing.MaxFileSizeMB, opt.MaxFileSizeMB) &&
+		boolPtrEqual(existing.CommitCommitterCheck, opt.CommitCommitterCheck) &&
+		boolPtrEqual(existing.RejectUnsignedCommits, opt.RejectUnsignedCommits) &&
+		stringPtrEqual(existing.PreventSecretsRegex, opt.PreventSecretsRegex)
+}
+
+func stringPtrEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func boolPtrEqual(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func intPtrEqual(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,351 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestPushRules_Validate_MultipleViolations(t *testing.T) {
+	commitMessageRegex := "^fix:"
+	authorEmailRegex := "@example\\.com$"
+	fileNameRegex := "\\.secret$"
+	maxFileSizeMB := 1
+
+	rules := &PushRules{
+		CommitMessageRegex: &commitMessageRegex,
+		AuthorEmailRegex:   &authorEmailRegex,
+		FileNameRegex:      &fileNameRegex,
+		MaxFileSizeMB:      &maxFileSizeMB,
+	}
+
+	commit := &Commit{
+		Message:        "oops, forgot the prefix",
+		CommitterEmail: "jane@other.org",
+	}
+	files := []CommitFile{
+		{Path: "keys.secret", Size: 10},
+		{Path: "big.bin", Size: 2 * 1024 * 1024},
+		{Path: "ok.go", Size: 10},
+	}
+
+	violations := rules.Validate(commit, files)
+
+	want := map[string]int{
+		"commit_message_regex": 1,
+		"author_email_regex":   1,
+		"file_name_regex":      1,
+		"max_file_size":        1,
+	}
+	got := map[string]int{}
+	for _, v := range violations {
+		got[v.Field]++
+	}
+	for field, count := range want {
+		if got[field] != count {
+			t.Errorf("violations[%q] = %d, want %d (all violations: %+v)", field, got[field], count, violations)
+		}
+	}
+	if len(violations) != 4 {
+		t.Errorf("len(violations) = %d, want 4: %+v", len(violations), violations)
+	}
+}
+
+func TestPushRules_Validate_NilCommitEmptyFiles(t *testing.T) {
+	commitMessageRegex := "^fix:"
+	rules := &PushRules{CommitMessageRegex: &commitMessageRegex}
+
+	if violations := rules.Validate(nil, nil); len(violations) != 0 {
+		t.Errorf("violations = %+v, want none", violations)
+	}
+}
+
+func TestPushRules_Validate_NoRulesConfigured(t *testing.T) {
+	rules := &PushRules{}
+	commit := &Commit{Message: "anything goes", CommitterEmail: "anyone@anywhere.org"}
+	files := []CommitFile{{Path: "whatever", Size: 1 << 30}}
+
+	if violations := rules.Validate(commit, files); len(violations) != 0 {
+		t.Errorf("violations = %+v, want none", violations)
+	}
+}
+
+func TestPushRules_Validate_PreventSecretsRegex(t *testing.T) {
+	preventSecretsRegex := "id_rsa$"
+	rules := &PushRules{PreventSecretsRegex: &preventSecretsRegex}
+
+	files := []CommitFile{
+		{Path: "keys/id_rsa", Size: 10},
+		{Path: "main.go", Size: 10},
+	}
+
+	violations := rules.Validate(nil, files)
+	if len(violations) != 1 || violations[0].Field != "prevent_secrets_regex" {
+		t.Fatalf("violations = %+v, want a single prevent_secrets_regex violation", violations)
+	}
+}
+
+// TestPushRules_Validate_ConcurrentCallsDoNotRace exercises the realistic
+// usage pattern of one shared PushRules (fetched once) validated from many
+// goroutines at once, e.g. a webhook HTTP handler calling CheckPushEvent
+// per request. Run with -race to catch regressions in the regex cache.
+func TestPushRules_Validate_ConcurrentCallsDoNotRace(t *testing.T) {
+	commitMessageRegex := "^fix:"
+	authorEmailRegex := "@example\\.com$"
+	fileNameRegex := "\\.secret$"
+	preventSecretsRegex := "id_rsa$"
+
+	rules := &PushRules{
+		CommitMessageRegex:  &commitMessageRegex,
+		AuthorEmailRegex:    &authorEmailRegex,
+		FileNameRegex:       &fileNameRegex,
+		PreventSecretsRegex: &preventSecretsRegex,
+	}
+
+	commit := &Commit{Message: "fix: thing", CommitterEmail: "jane@example.com"}
+	files := []CommitFile{{Path: "keys/id_rsa", Size: 10}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rules.Validate(commit, files)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestProjectsService_ApplyPushRule_AddsWhenMissing(t *testing.T) {
+	mux, client := setup(t)
+
+	var postCalled bool
+	mux.HandleFunc("/api/v4/projects/1/push_rule", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPost:
+			postCalled = true
+			fmt.Fprint(w, `{"id":1,"project_id":1,"commit_message_regex":"^JIRA-"}`)
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	})
+
+	regex := "^JIRA-"
+	rule, _, err := client.Projects.ApplyPushRule(1, &AddPushRulesOptions{CommitMessageRegex: &regex})
+	if err != nil {
+		t.Fatalf("ApplyPushRule returned error: %v", err)
+	}
+	if !postCalled {
+		t.Error("expected POST to be called when no push rule exists")
+	}
+	if rule.CommitMessageRegex == nil || *rule.CommitMessageRegex != regex {
+		t.Errorf("CommitMessageRegex = %v, want %q", rule.CommitMessageRegex, regex)
+	}
+}
+
+func TestProjectsService_ApplyPushRule_NoopWhenMatching(t *testing.T) {
+	mux, client := setup(t)
+
+	mux.HandleFunc("/api/v4/projects/1/push_rule", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"id":1,"project_id":1,"commit_message_regex":"^JIRA-"}`)
+		default:
+			t.Errorf("unexpected method %s, want GET only", r.Method)
+		}
+	})
+
+	regex := "^JIRA-"
+	rule, _, err := client.Projects.ApplyPushRule(1, &AddPushRulesOptions{CommitMessageRegex: &regex})
+	if err != nil {
+		t.Fatalf("ApplyPushRule returned error: %v", err)
+	}
+	if rule.ID != 1 {
+		t.Errorf("ID = %d, want 1", rule.ID)
+	}
+}
+
+func TestProjectsService_ApplyPushRule_EditsWithIfMatchWhenDiffering(t *testing.T) {
+	mux, client := setup(t)
+
+	const etag = `"abc123"`
+	var putIfMatch string
+
+	mux.HandleFunc("/api/v4/projects/1/push_rule", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("ETag", etag)
+			fmt.Fprint(w, `{"id":1,"project_id":1,"commit_message_regex":"^OLD-"}`)
+		case http.MethodPut:
+			putIfMatch = r.Header.Get("If-Match")
+			fmt.Fprint(w, `{"id":1,"project_id":1,"commit_message_regex":"^JIRA-"}`)
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	})
+
+	regex := "^JIRA-"
+	rule, _, err := client.Projects.ApplyPushRule(1, &AddPushRulesOptions{CommitMessageRegex: &regex})
+	if err != nil {
+		t.Fatalf("ApplyPushRule returned error: %v", err)
+	}
+	if putIfMatch != etag {
+		t.Errorf("If-Match = %q, want %q", putIfMatch, etag)
+	}
+	if rule.CommitMessageRegex == nil || *rule.CommitMessageRegex != regex {
+		t.Errorf("CommitMessageRegex = %v, want %q", rule.CommitMessageRegex, regex)
+	}
+}
+
+func TestGroupsService_GetGroupPushRule(t *testing.T) {
+	mux, client := setup(t)
+
+	mux.HandleFunc("/api/v4/groups/1/push_rule", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		fmt.Fprint(w, `{"id":1,"commit_message_regex":"^JIRA-"}`)
+	})
+
+	rule, _, err := client.Groups.GetGroupPushRule(1)
+	if err != nil {
+		t.Fatalf("GetGroupPushRule returned error: %v", err)
+	}
+	if rule.ID != 1 {
+		t.Errorf("ID = %d, want 1", rule.ID)
+	}
+}
+
+func TestGroupsService_AddGroupPushRule(t *testing.T) {
+	mux, client := setup(t)
+
+	mux.HandleFunc("/api/v4/groups/1/push_rule", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		fmt.Fprint(w, `{"id":1,"commit_message_regex":"^JIRA-"}`)
+	})
+
+	regex := "^JIRA-"
+	rule, _, err := client.Groups.AddGroupPushRule(1, &AddPushRulesOptions{CommitMessageRegex: &regex})
+	if err != nil {
+		t.Fatalf("AddGroupPushRule returned error: %v", err)
+	}
+	if rule.CommitMessageRegex == nil || *rule.CommitMessageRegex != regex {
+		t.Errorf("CommitMessageRegex = %v, want %q", rule.CommitMessageRegex, regex)
+	}
+}
+
+func TestGroupsService_EditGroupPushRule(t *testing.T) {
+	mux, client := setup(t)
+
+	mux.HandleFunc("/api/v4/groups/1/push_rule", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPut)
+		fmt.Fprint(w, `{"id":1,"commit_message_regex":"^JIRA-"}`)
+	})
+
+	regex := "^JIRA-"
+	rule, _, err := client.Groups.EditGroupPushRule(1, &EditPushRulesOptions{CommitMessageRegex: &regex})
+	if err != nil {
+		t.Fatalf("EditGroupPushRule returned error: %v", err)
+	}
+	if rule.CommitMessageRegex == nil || *rule.CommitMessageRegex != regex {
+		t.Errorf("CommitMessageRegex = %v, want %q", rule.CommitMessageRegex, regex)
+	}
+}
+
+func TestGroupsService_DeleteGroupPushRule(t *testing.T) {
+	mux, client := setup(t)
+
+	mux.HandleFunc("/api/v4/groups/1/push_rule", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodDelete)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := client.Groups.DeleteGroupPushRule(1)
+	if err != nil {
+		t.Fatalf("DeleteGroupPushRule returned error: %v", err)
+	}
+}
+
+func TestGroupsService_ApplyGroupPushRule_AddsWhenMissing(t *testing.T) {
+	mux, client := setup(t)
+
+	var postCalled bool
+	mux.HandleFunc("/api/v4/groups/1/push_rule", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPost:
+			postCalled = true
+			fmt.Fprint(w, `{"id":1,"commit_message_regex":"^JIRA-"}`)
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	})
+
+	regex := "^JIRA-"
+	rule, _, err := client.Groups.ApplyGroupPushRule(1, &AddPushRulesOptions{CommitMessageRegex: &regex})
+	if err != nil {
+		t.Fatalf("ApplyGroupPushRule returned error: %v", err)
+	}
+	if !postCalled {
+		t.Error("expected POST to be called when no push rule exists")
+	}
+	if rule.CommitMessageRegex == nil || *rule.CommitMessageRegex != regex {
+		t.Errorf("CommitMessageRegex = %v, want %q", rule.CommitMessageRegex, regex)
+	}
+}
+
+func TestGroupsService_ApplyGroupPushRule_NoopWhenMatching(t *testing.T) {
+	mux, client := setup(t)
+
+	mux.HandleFunc("/api/v4/groups/1/push_rule", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"id":1,"commit_message_regex":"^JIRA-"}`)
+		default:
+			t.Errorf("unexpected method %s, want GET only", r.Method)
+		}
+	})
+
+	regex := "^JIRA-"
+	rule, _, err := client.Groups.ApplyGroupPushRule(1, &AddPushRulesOptions{CommitMessageRegex: &regex})
+	if err != nil {
+		t.Fatalf("ApplyGroupPushRule returned error: %v", err)
+	}
+	if rule.ID != 1 {
+		t.Errorf("ID = %d, want 1", rule.ID)
+	}
+}
+
+func TestGroupsService_ApplyGroupPushRule_EditsWithIfMatchWhenDiffering(t *testing.T) {
+	mux, client := setup(t)
+
+	const etag = `"abc123"`
+	var putIfMatch string
+
+	mux.HandleFunc("/api/v4/groups/1/push_rule", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("ETag", etag)
+			fmt.Fprint(w, `{"id":1,"commit_message_regex":"^OLD-"}`)
+		case http.MethodPut:
+			putIfMatch = r.Header.Get("If-Match")
+			fmt.Fprint(w, `{"id":1,"commit_message_regex":"^JIRA-"}`)
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	})
+
+	regex := "^JIRA-"
+	rule, _, err := client.Groups.ApplyGroupPushRule(1, &AddPushRulesOptions{CommitMessageRegex: &regex})
+	if err != nil {
+		t.Fatalf("ApplyGroupPushRule returned error: %v", err)
+	}
+	if putIfMatch != etag {
+		t.Errorf("If-Match = %q, want %q", putIfMatch, etag)
+	}
+	if rule.CommitMessageRegex == nil || *rule.CommitMessageRegex != regex {
+		t.Errorf("CommitMessageRegex = %v, want %q", rule.CommitMessageRegex, regex)
+	}
+}